@@ -0,0 +1,186 @@
+// Package bar fait tourner tempo-edf comme une source de statusline i3bar au
+// lieu d'une icône systray, pour les utilisateurs de i3bar, waybar et
+// swaybar. Le protocole est documenté par i3 (man i3bar-protocol) : un
+// en-tête JSON, un tableau ouvert `[`, puis un flux d'éléments `[...]`, un
+// par ligne.
+package bar
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"tempo-edf/tempo"
+)
+
+const enedisURL = "https://www.enedis.fr"
+
+// blockName identifie notre bloc dans le flux i3bar (utile si d'autres
+// générateurs de blocs tournent dans la même barre).
+const blockName = "tempo-edf"
+
+// Palette associe chaque couleur Tempo à une couleur hexadécimale affichée
+// dans la barre. Les valeurs par défaut reprennent celles des icônes systray.
+type Palette struct {
+	Bleu  string
+	Blanc string
+	Rouge string
+}
+
+// DefaultPalette retourne les couleurs par défaut du mode bar.
+func DefaultPalette() Palette {
+	return Palette{
+		Bleu:  "#4a90d9",
+		Blanc: "#d9d9d9",
+		Rouge: "#d94a4a",
+	}
+}
+
+func (p Palette) colorFor(name string) string {
+	switch strings.ToUpper(name) {
+	case "BLEU":
+		return p.Bleu
+	case "BLANC":
+		return p.Blanc
+	case "ROUGE":
+		return p.Rouge
+	default:
+		return ""
+	}
+}
+
+// block est un élément du tableau i3bar (voir i3bar-protocol(7)).
+type block struct {
+	FullText   string `json:"full_text"`
+	ShortText  string `json:"short_text,omitempty"`
+	Color      string `json:"color,omitempty"`
+	Background string `json:"background,omitempty"`
+	Name       string `json:"name"`
+	Instance   string `json:"instance"`
+}
+
+// clickEvent est l'événement lu sur stdin lorsque l'utilisateur clique sur
+// notre bloc (voir i3bar-protocol(7), "click_events").
+type clickEvent struct {
+	Name     string `json:"name"`
+	Instance string `json:"instance"`
+	Button   int    `json:"button"`
+}
+
+// showTomorrow bascule l'affichage entre aujourd'hui et demain au clic droit.
+// Lue par la goroutine d'écriture des frames et modifiée par celle de
+// lecture des clics : atomic.Bool évite la course entre les deux.
+var showTomorrow atomic.Bool
+
+// Run démarre le mode bar : il écrit un en-tête i3bar puis pousse une
+// nouvelle frame à chaque mise à jour des données (y compris celles
+// déclenchées par les clics gauche et droit), jusqu'à fermeture de stdout. Cette fonction ne retourne qu'en cas d'erreur.
+func Run(svc *tempo.Service, logger *slog.Logger, palette Palette) error {
+	out := bufio.NewWriter(os.Stdout)
+	header := struct {
+		Version     int  `json:"version"`
+		ClickEvents bool `json:"click_events"`
+	}{Version: 1, ClickEvents: true}
+
+	if err := json.NewEncoder(out).Encode(header); err != nil {
+		return fmt.Errorf("écriture en-tête i3bar: %w", err)
+	}
+	fmt.Fprintln(out, "[")
+	out.Flush()
+
+	writeFrame := func() error {
+		b := buildBlock(svc.CurrentData(), palette)
+		line, err := json.Marshal([]block{b})
+		if err != nil {
+			return fmt.Errorf("encodage bloc i3bar: %w", err)
+		}
+		if _, err := fmt.Fprintf(out, "%s,\n", line); err != nil {
+			return err
+		}
+		return out.Flush()
+	}
+
+	if err := writeFrame(); err != nil {
+		return err
+	}
+
+	updates := svc.Subscribe()
+	defer svc.Unsubscribe(updates)
+
+	go readClicks(svc, logger)
+
+	for range updates {
+		if err := writeFrame(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildBlock traduit les données Tempo courantes en bloc i3bar.
+func buildBlock(d tempo.Data, palette Palette) block {
+	color := d.TodayColor
+	label := "Aujourd'hui"
+	if showTomorrow.Load() {
+		color = d.TomorrowColor
+		label = "Demain"
+	}
+	return block{
+		FullText:  fmt.Sprintf("Tempo %s: %s (%.3f€/kWh)", label, color, d.CurrentTarif),
+		ShortText: color,
+		Color:     palette.colorFor(color),
+		Name:      blockName,
+		Instance:  blockName,
+	}
+}
+
+// readClicks lit les événements de clic envoyés par la barre sur stdin et
+// les traduit en actions : clic gauche = refresh, clic droit = bascule
+// aujourd'hui/demain, clic du milieu = ouverture du site ENEDIS.
+func readClicks(svc *tempo.Service, logger *slog.Logger) {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		// Le flux est un tableau JSON sans fin : "[" puis un événement par
+		// ligne, les suivants préfixés par ",".
+		line := strings.TrimSpace(strings.Trim(strings.TrimSpace(scanner.Text()), "[,"))
+		if line == "" {
+			continue
+		}
+		var ev clickEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			logger.Debug("événement clic i3bar invalide", "error", err, "line", line)
+			continue
+		}
+		switch ev.Button {
+		case 1: // clic gauche
+			svc.UpdateData()
+		case 2: // clic du milieu
+			if err := openURL(enedisURL); err != nil {
+				logger.Error("ouverture du site ENEDIS", "error", err)
+			}
+		case 3: // clic droit
+			showTomorrow.Store(!showTomorrow.Load())
+			svc.UpdateData()
+		}
+	}
+}
+
+// openURL ouvre url dans le navigateur par défaut de l'OS.
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
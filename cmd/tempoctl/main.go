@@ -0,0 +1,75 @@
+// Command tempoctl est un client en ligne de commande pour le daemon
+// tempo-edf : il parle le protocole JSON-lines du socket de contrôle local
+// exposé par le daemon, ce qui permet de scripter des notifications,
+// des tâches cron ou un prompt shell sans dupliquer les appels à l'API.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"tempo-edf/ipc"
+)
+
+func main() {
+	socketPath := flag.String("socket", "", "chemin du socket de contrôle (défaut: socket par défaut du daemon installé dans le répertoire de tempoctl)")
+	asJSON := flag.Bool("json", false, "affiche la réponse brute en JSON")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: tempoctl [--socket path] [--json] <refresh|get|subscribe>")
+		os.Exit(2)
+	}
+
+	addr := *socketPath
+	if addr == "" {
+		exePath, err := os.Executable()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "détection chemin exécutable: %v\n", err)
+			os.Exit(1)
+		}
+		addr = ipc.DefaultSocketPath(filepath.Dir(exePath))
+	}
+
+	client := ipc.NewClient(addr)
+
+	switch cmd := ipc.Command(flag.Arg(0)); cmd {
+	case ipc.CmdRefresh, ipc.CmdGet:
+		resp, err := client.Call(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "erreur: %v\n", err)
+			os.Exit(1)
+		}
+		printResponse(resp, *asJSON)
+
+	case ipc.CmdSubscribe:
+		err := client.Subscribe(func(resp ipc.Response) error {
+			printResponse(resp, *asJSON)
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+	default:
+		fmt.Fprintf(os.Stderr, "commande inconnue: %s (attendu: refresh, get, subscribe)\n", cmd)
+		os.Exit(2)
+	}
+}
+
+func printResponse(resp ipc.Response, asJSON bool) {
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		_ = enc.Encode(resp)
+		return
+	}
+	if resp.Data == nil {
+		return
+	}
+	fmt.Printf("aujourd'hui=%s demain=%s tarif=%.3f€/kWh maj=%s\n",
+		resp.Data.TodayColor, resp.Data.TomorrowColor, resp.Data.CurrentTarif, resp.Data.LastUpdated.Format("15:04:05"))
+}
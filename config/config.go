@@ -0,0 +1,147 @@
+// Package config charge la configuration de tempo-edf depuis un fichier
+// TOML (sur le modèle du itd.toml du projet itd), avec des valeurs par
+// défaut qui reproduisent le comportement historique codé en dur, et un
+// rechargement à chaud via Watch.
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Duration se (dé)sérialise en TOML sous forme de chaîne ("10s", "30m"),
+// comme accepté par time.ParseDuration.
+type Duration time.Duration
+
+func (d Duration) Get() time.Duration { return time.Duration(d) }
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("durée invalide %q: %w", text, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Notifications regroupe les préférences de notification. Elles ne
+// s'appliquent qu'à la notification de changement de jour envoyée à minuit ;
+// la notification du démarrage et celles déclenchées depuis le menu sont
+// toujours envoyées.
+type Notifications struct {
+	// SilentStart/SilentEnd délimitent une plage "HH:MM"-"HH:MM" (heure
+	// locale) pendant laquelle la notification de changement de jour n'est
+	// pas envoyée. Vides tous les deux, la plage silencieuse est désactivée.
+	SilentStart string `toml:"silent_start"`
+	SilentEnd   string `toml:"silent_end"`
+	// ColorChangeOnly n'envoie la notification de changement de jour que si
+	// la couleur du jour diffère de celle de la veille.
+	ColorChangeOnly bool `toml:"notify_on_color_change_only"`
+	// RougeOnly n'envoie la notification de changement de jour que pour les
+	// jours ROUGE.
+	RougeOnly bool `toml:"notify_on_rouge_only"`
+}
+
+// Icons regroupe les chemins des thèmes d'icônes.
+type Icons struct {
+	// ThemeDir, si non vide, est utilisé à la place de appDir/assets pour
+	// charger les icônes (permet d'installer des thèmes alternatifs).
+	ThemeDir string `toml:"theme_dir"`
+}
+
+// Features active ou désactive des fonctionnalités optionnelles.
+type Features struct {
+	BarMode       bool   `toml:"bar_mode"`
+	IPCSocketPath string `toml:"ipc_socket_path"`
+	QuotaTracker  bool   `toml:"quota_tracker"`
+	// MetricsPort, si non nul, démarre un exporteur Prometheus/OpenMetrics
+	// sur http://localhost:<port>/metrics. Désactivé par défaut (0).
+	MetricsPort int `toml:"metrics_port"`
+}
+
+// Config est la configuration complète de tempo-edf.
+type Config struct {
+	APIURL   string   `toml:"api_url"`
+	Timeout  Duration `toml:"timeout"`
+	CacheTTL Duration `toml:"cache_ttl"`
+	LogLevel string   `toml:"log_level"`
+
+	Notifications Notifications `toml:"notifications"`
+	Icons         Icons         `toml:"icons"`
+	Features      Features      `toml:"features"`
+}
+
+// Defaults retourne la configuration par défaut, identique au comportement
+// de tempo-edf avant l'introduction du fichier TOML.
+func Defaults() Config {
+	return Config{
+		APIURL:   "https://www.api-couleur-tempo.fr/api",
+		Timeout:  Duration(10 * time.Second),
+		CacheTTL: Duration(30 * time.Minute),
+		LogLevel: "info",
+		Features: Features{
+			QuotaTracker: true,
+		},
+	}
+}
+
+// Validate rejette les valeurs incohérentes avec un message clair, plutôt
+// que de laisser l'application paniquer ou tourner avec un état absurde.
+func (c Config) Validate() error {
+	if c.APIURL == "" {
+		return fmt.Errorf("api_url ne peut pas être vide")
+	}
+	if c.Timeout.Get() <= 0 {
+		return fmt.Errorf("timeout doit être positif (valeur: %s)", c.Timeout.Get())
+	}
+	if c.CacheTTL.Get() < time.Minute {
+		return fmt.Errorf("cache_ttl doit être >= 1m (valeur: %s)", c.CacheTTL.Get())
+	}
+	if _, err := parseLogLevel(c.LogLevel); err != nil {
+		return err
+	}
+	if c.Features.MetricsPort < 0 || c.Features.MetricsPort > 65535 {
+		return fmt.Errorf("features.metrics_port doit être compris entre 0 (désactivé) et 65535 (valeur: %d)", c.Features.MetricsPort)
+	}
+	return nil
+}
+
+// Load lit path et retourne la configuration qui en résulte. Si le fichier
+// n'existe pas, les valeurs par défaut sont écrites à cet emplacement puis
+// retournées, pour que les utilisateurs existants ne soient pas affectés.
+func Load(path string) (Config, error) {
+	cfg := Defaults()
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := writeDefault(path, cfg); err != nil {
+			return cfg, fmt.Errorf("écriture configuration par défaut %s: %w", path, err)
+		}
+		return cfg, nil
+	}
+
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		return cfg, fmt.Errorf("lecture configuration %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("configuration invalide %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+func writeDefault(path string, cfg Config) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return toml.NewEncoder(f).Encode(cfg)
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// parseLogLevel traduit la chaîne log_level de la configuration en niveau slog.
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("log_level inconnu: %q (attendu: debug, info, warn, error)", level)
+	}
+}
+
+// LogLevelValue retourne le niveau slog correspondant à c.LogLevel. Puisque
+// Validate a déjà rejeté les valeurs invalides, l'erreur est ignorée ici.
+func (c Config) LogLevelValue() slog.Level {
+	lvl, _ := parseLogLevel(c.LogLevel)
+	return lvl
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch surveille path et appelle onChange avec la configuration rechargée à
+// chaque écriture. Une configuration invalide est journalisée et ignorée :
+// la dernière configuration valide reste en vigueur. Watch ne retourne
+// qu'en cas d'échec de la mise en place de la surveillance ; les erreurs
+// signalées ensuite par fsnotify sont journalisées et la surveillance
+// continue. Elle est destinée à tourner dans sa propre goroutine.
+//
+// On surveille le répertoire parent plutôt que path lui-même : beaucoup
+// d'éditeurs remplacent le fichier par un
+// rename atomique, ce qui détache un watch posé directement sur l'inode
+// d'origine et rend la surveillance muette après la première sauvegarde.
+func Watch(path string, logger *slog.Logger, onChange func(Config)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+
+	if err := watcher.Add(dir); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Base(event.Name) != base {
+				continue
+			}
+			// Beaucoup d'éditeurs remplacent le fichier via rename+create;
+			// on réagit aux deux pour ne pas manquer une sauvegarde.
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			cfg, err := Load(path)
+			if err != nil {
+				logger.Error("Configuration rechargée invalide, conservation de la précédente", "error", err)
+				continue
+			}
+			logger.Info("Configuration rechargée", "path", path)
+			onChange(cfg)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Error("Erreur de surveillance de la configuration", "error", err)
+		}
+	}
+}
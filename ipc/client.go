@@ -0,0 +1,67 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+)
+
+// Client se connecte au socket de contrôle du daemon tempo-edf pour lui
+// envoyer une commande et lire la ou les réponses associées.
+type Client struct {
+	addr string
+}
+
+// NewClient construit un Client ciblant le socket/pipe nommé addr.
+func NewClient(addr string) *Client {
+	return &Client{addr: addr}
+}
+
+// Call envoie une commande unique et retourne la première réponse. Destiné
+// à refresh et get.
+func (c *Client) Call(cmd Command) (Response, error) {
+	conn, err := dial(c.addr)
+	if err != nil {
+		return Response{}, err
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(Request{Command: cmd}); err != nil {
+		return Response{}, fmt.Errorf("envoi requête IPC: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("lecture réponse IPC: %w", err)
+	}
+	if !resp.OK {
+		return resp, fmt.Errorf("erreur serveur: %s", resp.Err)
+	}
+	return resp, nil
+}
+
+// Subscribe envoie la commande subscribe et appelle fn pour chaque réponse
+// reçue jusqu'à ce que la connexion se ferme ou que fn retourne une erreur.
+func (c *Client) Subscribe(fn func(Response) error) error {
+	conn, err := dial(c.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(Request{Command: CmdSubscribe}); err != nil {
+		return fmt.Errorf("envoi requête IPC: %w", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			return fmt.Errorf("parsing réponse IPC: %w", err)
+		}
+		if err := fn(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,35 @@
+// Package ipc implémente le protocole de contrôle local de tempo-edf : un
+// socket Unix sur Linux/macOS, un pipe nommé sur Windows, avec des messages
+// JSON délimités par des sauts de ligne (JSON-lines). Le daemon expose un
+// Server, tempoctl s'appuie sur un Client.
+package ipc
+
+import "tempo-edf/tempo"
+
+// Command identifie l'opération demandée par un client.
+type Command string
+
+const (
+	// CmdRefresh force une nouvelle interrogation de l'API Tempo.
+	CmdRefresh Command = "refresh"
+	// CmdGet renvoie l'état courant (données + statistiques de cache).
+	CmdGet Command = "get"
+	// CmdSubscribe passe la connexion en mode flux : une ligne JSON est
+	// envoyée à chaque mise à jour des données, jusqu'à fermeture du socket.
+	CmdSubscribe Command = "subscribe"
+)
+
+// Request est la ligne JSON envoyée par un client au serveur.
+type Request struct {
+	Command Command `json:"command"`
+}
+
+// Response est la ligne JSON renvoyée par le serveur. Err est vide en cas de
+// succès. Data est présent pour refresh, get et subscribe ; Stats
+// uniquement pour get.
+type Response struct {
+	OK    bool         `json:"ok"`
+	Err   string       `json:"error,omitempty"`
+	Data  *tempo.Data  `json:"data,omitempty"`
+	Stats *tempo.Stats `json:"stats,omitempty"`
+}
@@ -0,0 +1,109 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"net"
+
+	"tempo-edf/tempo"
+)
+
+// Server écoute les connexions de contrôle et répond aux commandes refresh,
+// get et subscribe en s'appuyant sur un *tempo.Service partagé avec le reste
+// du daemon.
+type Server struct {
+	addr    string
+	service *tempo.Service
+	logger  *slog.Logger
+	ln      net.Listener
+}
+
+// NewServer construit un Server prêt à être démarré avec Serve.
+func NewServer(addr string, service *tempo.Service, logger *slog.Logger) *Server {
+	return &Server{addr: addr, service: service, logger: logger}
+}
+
+// Serve accepte les connexions jusqu'à ce que Close soit appelé. Elle est
+// destinée à tourner dans une goroutine dédiée.
+func (srv *Server) Serve() error {
+	ln, err := listen(srv.addr)
+	if err != nil {
+		return err
+	}
+	srv.ln = ln
+	srv.logger.Info("IPC en écoute", "addr", srv.addr)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go srv.handleConn(conn)
+	}
+}
+
+// Close arrête l'écoute du serveur.
+func (srv *Server) Close() error {
+	if srv.ln == nil {
+		return nil
+	}
+	return srv.ln.Close()
+}
+
+func (srv *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	dec := json.NewDecoder(conn)
+	bw := bufio.NewWriter(conn)
+	enc := json.NewEncoder(bw)
+
+	var req Request
+	if err := dec.Decode(&req); err != nil {
+		srv.logger.Debug("IPC requête invalide", "error", err)
+		return
+	}
+
+	switch req.Command {
+	case CmdRefresh:
+		srv.service.UpdateData()
+		d := srv.service.CurrentData()
+		srv.writeFlush(bw, enc, Response{OK: true, Data: &d})
+
+	case CmdGet:
+		d := srv.service.CurrentData()
+		stats := srv.service.Stats()
+		srv.writeFlush(bw, enc, Response{OK: true, Data: &d, Stats: &stats})
+
+	case CmdSubscribe:
+		ch := srv.service.Subscribe()
+		defer srv.service.Unsubscribe(ch)
+		// Envoie immédiatement l'état courant, puis chaque mise à jour.
+		d := srv.service.CurrentData()
+		if !srv.writeFlush(bw, enc, Response{OK: true, Data: &d}) {
+			return
+		}
+		for d := range ch {
+			if !srv.writeFlush(bw, enc, Response{OK: true, Data: &d}) {
+				return
+			}
+		}
+
+	default:
+		srv.writeFlush(bw, enc, Response{OK: false, Err: "commande inconnue: " + string(req.Command)})
+	}
+}
+
+// writeFlush encode une réponse JSON-lines et vide le buffer de sortie.
+// Retourne false si l'écriture a échoué (client déconnecté).
+func (srv *Server) writeFlush(bw *bufio.Writer, enc *json.Encoder, resp Response) bool {
+	if err := enc.Encode(resp); err != nil {
+		srv.logger.Debug("IPC écriture échouée", "error", err)
+		return false
+	}
+	if err := bw.Flush(); err != nil {
+		srv.logger.Debug("IPC flush échoué", "error", err)
+		return false
+	}
+	return true
+}
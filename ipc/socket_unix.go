@@ -0,0 +1,38 @@
+//go:build !windows
+
+package ipc
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// DefaultSocketPath retourne le chemin du socket Unix de contrôle, sous
+// appDir pour rester cohérent avec le cache et les logs de l'application.
+func DefaultSocketPath(appDir string) string {
+	return filepath.Join(appDir, "tempo-edf.sock")
+}
+
+// listen crée le socket Unix d'écoute, en supprimant une éventuelle socket
+// orpheline laissée par un précédent arrêt non propre.
+func listen(addr string) (net.Listener, error) {
+	if _, err := os.Stat(addr); err == nil {
+		os.Remove(addr)
+	}
+	l, err := net.Listen("unix", addr)
+	if err != nil {
+		return nil, fmt.Errorf("écoute socket unix %s: %w", addr, err)
+	}
+	return l, nil
+}
+
+// dial se connecte au socket Unix de contrôle.
+func dial(addr string) (net.Conn, error) {
+	conn, err := net.Dial("unix", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connexion socket unix %s: %w", addr, err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+package ipc
+
+import (
+	"fmt"
+	"net"
+
+	winio "github.com/Microsoft/go-winio"
+)
+
+// DefaultSocketPath retourne le nom du pipe nommé de contrôle. appDir est
+// ignoré sous Windows: les pipes nommés vivent dans un espace de noms global.
+func DefaultSocketPath(appDir string) string {
+	return `\\.\pipe\tempo-edf`
+}
+
+// listen crée le pipe nommé d'écoute.
+func listen(addr string) (net.Listener, error) {
+	l, err := winio.ListenPipe(addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("écoute pipe nommé %s: %w", addr, err)
+	}
+	return l, nil
+}
+
+// dial se connecte au pipe nommé de contrôle.
+func dial(addr string) (net.Conn, error) {
+	conn, err := winio.DialPipe(addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connexion pipe nommé %s: %w", addr, err)
+	}
+	return conn, nil
+}
@@ -1,51 +1,54 @@
 package main
 
 import (
-	"encoding/json"
-	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log/slog"
-	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/getlantern/systray"
 	"github.com/gen2brain/beeep"
-)
+	"github.com/getlantern/systray"
 
-const (
-	defaultAPIURL = "https://www.api-couleur-tempo.fr/api"
-	defaultTimeout = 10 * time.Second
+	"tempo-edf/bar"
+	"tempo-edf/config"
+	"tempo-edf/ipc"
+	"tempo-edf/metrics"
+	"tempo-edf/quota"
+	"tempo-edf/startup"
+	"tempo-edf/tempo"
 )
 
 var (
-	logger        *slog.Logger
-	httpClient    = &http.Client{Timeout: defaultTimeout}
-	config        = Config{
-		APIURL:   defaultAPIURL,
-		Timeout:  defaultTimeout,
-		CacheTTL: 30 * time.Minute,
-	}
-	dataMaj     sync.RWMutex
-	currentData = &Data{}
-	cache       = make(map[string]*cacheEntry)
-	cacheMu     sync.RWMutex
+	logger       *slog.Logger
+	svc          *tempo.Service
+	ipcSrv       *ipc.Server
+	quotaTracker *quota.Tracker
+	metricsSrv   *metrics.Exporter
 
 	// Icônes chargées dynamiquement en fonction de l'OS
 	icnBlanc []byte
 	icnRouge []byte
 	icnBleu  []byte
 
+	// themeDir est le thème d'icônes courant, appliqué par applyIconTheme
+	// depuis cfg.Icons.ThemeDir. Vide: thème par défaut sous appDir/assets.
+	themeDir string
+
 	// Windows-specific
-	exePath string
-	appDir  string
+	exePath     string
+	appDir      string
 	startupItem *systray.MenuItem
+
+	// Éléments de menu dont les libellés dépendent de la configuration
+	// (rechargés à chaud par onConfigChange/refreshMenuStrings).
+	refreshItem *systray.MenuItem
+	purgeItem   *systray.MenuItem
 )
 
 // init charge les icônes en fonction de l'OS et détecte le chemin de l'exécutable (Windows)
@@ -64,14 +67,14 @@ func init() {
 		blancExt = "assets/white.ico"
 		rougeExt = "assets/red.ico"
 		bleuExt = "assets/blue.ico"
-	//case "darwin": // macOS : utiliser PNG pour meilleur rendu en couleur
-	//	blancExt = "assets/icon_white.png"
-	//	rougeExt = "assets/icon_red.png"
-	//	bleuExt = "assets/icon_blue.png"
-	//default: // linux et autres
-	//	blancExt = "assets/icon_white.png"
-	//	rougeExt = "assets/icon_red.png"
-	//	bleuExt = "assets/icon_blue.png"
+		//case "darwin": // macOS : utiliser PNG pour meilleur rendu en couleur
+		//	blancExt = "assets/icon_white.png"
+		//	rougeExt = "assets/icon_red.png"
+		//	bleuExt = "assets/icon_blue.png"
+		//default: // linux et autres
+		//	blancExt = "assets/icon_white.png"
+		//	rougeExt = "assets/icon_red.png"
+		//	bleuExt = "assets/icon_blue.png"
 	}
 	icnBlanc = mustAsset(blancExt)
 	icnRouge = mustAsset(rougeExt)
@@ -91,40 +94,10 @@ func mustAsset(relPath string) []byte {
 	return data
 }
 
-// Config contient les configurations de l'application
-type Config struct {
-	APIURL    string
-	Timeout   time.Duration
-	CacheTTL  time.Duration
-}
-
-// Data stocke les données tempo actuelles
-type Data struct {
-	CurrentTarif  float64
-	TarifLib      string
-	TodayColor    string
-	TomorrowColor string
-	LastUpdated   time.Time
-}
-
-// TempoResponse représente la réponse de l'API Tempo
-type TempoResponse struct {
-	DateJour string `json:"dateJour"`
-	CodeJour int    `json:"codeJour"`
-	Periode  string `json:"periode"`
-	// LibCouleur absent de l'API, donc non utilisé
-}
-
-// NowResponse représente la réponse de l'API now
-type NowResponse struct {
-	ApplicableIn int     `json:"applicableIn"`
-	CodeCouleur  int     `json:"codeCouleur"`
-	CodeHoraire  int     `json:"codeHoraire"`
-	TarifKwh     float64 `json:"tarifKwh"`
-	LibTarif     string  `json:"libTarif"`
-}
-
 func main() {
+	barFlag := flag.Bool("bar", false, "sort en mode statusline i3bar/waybar/swaybar sur stdout au lieu de l'icône systray")
+	flag.Parse()
+
 	// Initialize logger with structured logging (stdout + fichier pour debug démarrage)
 	logFile, err := os.OpenFile(filepath.Join(appDir, "tempo_edf.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
@@ -132,50 +105,113 @@ func main() {
 	}
 	mw := io.MultiWriter(os.Stdout, logFile)
 	logger = slog.New(slog.NewTextHandler(mw, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: &logLevel,
 	}))
 
+	cfgPath := filepath.Join(appDir, "tempo-edf.toml")
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		logger.Error("Erreur chargement configuration, utilisation des valeurs par défaut", "error", err)
+		cfg = config.Defaults()
+	}
+	applyConfig(cfg)
+	applyIconTheme(cfg)
+
+	svc = tempo.NewService(tempo.Config{
+		APIURL:   cfg.APIURL,
+		Timeout:  cfg.Timeout.Get(),
+		CacheTTL: cfg.CacheTTL.Get(),
+		CacheDir: filepath.Join(appDir, "cache"),
+	}, logger)
+
 	logger.Info("Tempo EDF démarré", "exePath", exePath, "appDir", appDir)
-	systray.Run(onReady, onExit)
-}
 
-// isInStartup vérifie si l'application est déjà dans le registre de démarrage Windows
-func isInStartup() bool {
-	if runtime.GOOS != "windows" || exePath == "" {
-		return false
+	if cfg.Features.QuotaTracker {
+		quotaTracker = quota.NewTracker(filepath.Join(appDir, "season.json"), logger)
+		// Le backfill peut représenter des centaines de requêtes séquentielles
+		// (une par jour manqué) : il tourne en arrière-plan pour ne pas retarder
+		// l'affichage de l'icône. svc.UpdateData déclenche une diffusion qui
+		// rafraîchira les jauges de quota du menu une fois terminé.
+		go func() {
+			if err := quotaTracker.Backfill(time.Now(), svc.ColorForDate); err != nil {
+				logger.Warn("Erreur backfill quota saisonnier", "error", err)
+			}
+			svc.UpdateData()
+		}()
 	}
-	cmd := exec.Command("reg", "query", `HKCU\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`, "/v", "TempoEDF")
-	if err := cmd.Run(); err != nil {
-		return false
+
+	go func() {
+		if err := config.Watch(cfgPath, logger, onConfigChange); err != nil {
+			logger.Warn("Arrêt de la surveillance de la configuration", "error", err)
+		}
+	}()
+
+	// Démarre le serveur IPC (socket Unix / pipe nommé) avant l'UI pour que
+	// tempoctl puisse s'y connecter dès que le daemon est prêt.
+	socketPath := cfg.Features.IPCSocketPath
+	if socketPath == "" {
+		socketPath = ipc.DefaultSocketPath(appDir)
 	}
-	return true
-}
+	ipcSrv = ipc.NewServer(socketPath, svc, logger)
+	go func() {
+		if err := ipcSrv.Serve(); err != nil {
+			logger.Error("Arrêt du serveur IPC", "error", err)
+		}
+	}()
 
-// addToStartup ajoute l'application au démarrage via le registre Windows
-func addToStartup() error {
-	if runtime.GOOS != "windows" || exePath == "" {
-		return errors.New("opération non supportée ou chemin non détecté")
+	if cfg.Features.MetricsPort > 0 {
+		metricsSrv = metrics.NewExporter(fmt.Sprintf(":%d", cfg.Features.MetricsPort), svc, quotaTracker, logger)
+		go func() {
+			if err := metricsSrv.Serve(); err != nil {
+				logger.Error("Arrêt de l'exporteur de métriques", "error", err)
+			}
+		}()
 	}
-	quotedPath := `"` + exePath + `"`
-	cmd := exec.Command("reg", "add", `HKCU\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`, "/v", "TempoEDF", "/t", "REG_SZ", "/d", quotedPath, "/f")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("échec ajout au démarrage: %w", err)
+
+	go scheduleMidnightNotification()
+
+	if *barFlag || cfg.Features.BarMode {
+		svc.UpdateData()
+		if err := bar.Run(svc, logger, bar.DefaultPalette()); err != nil {
+			logger.Error("Arrêt du mode bar", "error", err)
+			os.Exit(1)
+		}
+		return
 	}
-	logger.Info("Application ajoutée au démarrage Windows")
-	return nil
+
+	systray.Run(onReady, onExit)
 }
 
-// removeFromStartup supprime l'application du démarrage Windows
-func removeFromStartup() error {
-	if runtime.GOOS != "windows" {
-		return errors.New("opération non supportée")
-	}
-	cmd := exec.Command("reg", "delete", `HKCU\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`, "/v", "TempoEDF", "/f")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("échec suppression du démarrage: %w", err)
+// logLevel est le niveau de log courant, modifiable à chaud par
+// applyConfig/onConfigChange sans recréer le logger.
+var logLevel slog.LevelVar
+
+// configMu protège currentCfg, lu par les notifications et réécrit par
+// onConfigChange lors d'un rechargement à chaud du fichier TOML.
+var (
+	configMu   sync.RWMutex
+	currentCfg config.Config
+)
+
+// applyConfig installe cfg comme configuration courante et applique son
+// niveau de log. Utilisé au démarrage et à chaque rechargement à chaud.
+func applyConfig(cfg config.Config) {
+	configMu.Lock()
+	currentCfg = cfg
+	configMu.Unlock()
+	logLevel.Set(cfg.LogLevelValue())
+}
+
+// onConfigChange est appelé par config.Watch à chaque modification valide du
+// fichier TOML: elle réapplique le niveau de log, le timeout HTTP, la durée
+// de vie du cache et les libellés de menu, sans redémarrer le tray.
+func onConfigChange(cfg config.Config) {
+	applyConfig(cfg)
+	if svc != nil {
+		svc.SetTimeout(cfg.Timeout.Get())
+		svc.SetCacheTTL(cfg.CacheTTL.Get())
 	}
-	logger.Info("Application supprimée du démarrage Windows")
-	return nil
+	refreshMenuStrings()
 }
 
 func onReady() {
@@ -184,30 +220,43 @@ func onReady() {
 	systray.SetTooltip("Tempo EDF - Couleur du jour")
 
 	// Initialize colors and tarifs
-	updateData()
+	svc.UpdateData()
 	updateIconBasedOnColor() // Mise à jour icône après données
 
+	data := svc.CurrentData()
+
 	// Send initial notification
 	sendNotification("Tempo EDF", fmt.Sprintf("Couleur d'aujourd'hui : %s - Tarif : %.3f€/kWh",
-		currentData.TodayColor, currentData.CurrentTarif), "")
+		data.TodayColor, data.CurrentTarif), "")
+	notifyUpcomingRouge(data)
 
 	// Create menu items with dynamic content
-	todayItem := systray.AddMenuItem(fmt.Sprintf("Aujourd'hui : %s", currentData.TodayColor), "Couleur d'aujourd'hui")
-	todayItem.SetTooltip(fmt.Sprintf("Couleur d'aujourd'hui : %s", currentData.TodayColor))
-
-	tomorrowItem := systray.AddMenuItem(fmt.Sprintf("Demain : %s", currentData.TomorrowColor), "Couleur de demain")
-	tomorrowItem.SetTooltip(fmt.Sprintf("Couleur de demain : %s", currentData.TomorrowColor))
-
-	tarifsItem := systray.AddMenuItem(fmt.Sprintf("Tarif actuel : %.3f€/kWh", currentData.CurrentTarif), "Tarifs Tempo EDF")
-	tarifsItem.SetTooltip(fmt.Sprintf("Tarif actuel : %.3f€/kWh - %s", currentData.CurrentTarif, currentData.TarifLib))
-
-	refreshItem := systray.AddMenuItem("Rafraîchir", "Rafraîchir les données")
-	refreshItem.SetTooltip("Rafraîchir les données")
+	todayItem := systray.AddMenuItem(fmt.Sprintf("Aujourd'hui : %s", data.TodayColor), "Couleur d'aujourd'hui")
+	todayItem.SetTooltip(fmt.Sprintf("Couleur d'aujourd'hui : %s", data.TodayColor))
+
+	tomorrowItem := systray.AddMenuItem(fmt.Sprintf("Demain : %s", data.TomorrowColor), "Couleur de demain")
+	tomorrowItem.SetTooltip(fmt.Sprintf("Couleur de demain : %s", data.TomorrowColor))
+
+	tarifsItem := systray.AddMenuItem(fmt.Sprintf("Tarif actuel : %.3f€/kWh", data.CurrentTarif), "Tarifs Tempo EDF")
+	tarifsItem.SetTooltip(fmt.Sprintf("Tarif actuel : %.3f€/kWh - %s", data.CurrentTarif, data.TarifLib))
+
+	refreshItem = systray.AddMenuItem("Rafraîchir", "Rafraîchir les données")
+	purgeItem = systray.AddMenuItem("Purger le cache", "Vider le cache mémoire et disque")
+	refreshMenuStrings()
+
+	var quotaBleuItem, quotaBlancItem, quotaRougeItem *systray.MenuItem
+	if quotaTracker != nil {
+		systray.AddSeparator()
+		counts := quotaTracker.Counts()
+		quotaBleuItem = systray.AddMenuItem(quota.FormatLine("BLEU", counts.Bleu, quota.Caps.Bleu, 20), "Jours BLEU consommés cette saison")
+		quotaBlancItem = systray.AddMenuItem(quota.FormatLine("BLANC", counts.Blanc, quota.Caps.Blanc, 20), "Jours BLANC consommés cette saison")
+		quotaRougeItem = systray.AddMenuItem(quota.FormatLine("ROUGE", counts.Rouge, quota.Caps.Rouge, 20), "Jours ROUGE consommés cette saison")
+	}
 
-	// Option démarrage Windows (uniquement si Windows)
-	if runtime.GOOS == "windows" && exePath != "" {
-		checked := isInStartup()
-		startupItem = systray.AddMenuItemCheckbox("Démarrer avec Windows", "Lancer au démarrage du PC", checked)
+	// Option démarrage automatique (Windows, Linux, macOS)
+	if exePath != "" {
+		checked := startup.IsEnabled(exePath)
+		startupItem = systray.AddMenuItemCheckbox("Démarrer avec la session", "Lancer au démarrage de la session", checked)
 		startupItem.SetTooltip("Ajouter/supprimer du démarrage automatique")
 	}
 
@@ -217,38 +266,53 @@ func onReady() {
 	quitItem.SetTooltip("Quitter l'application")
 
 	// Handle menu item clicks
-	go handleMenuClicks(todayItem, tomorrowItem, tarifsItem, refreshItem, quitItem)
+	go handleMenuClicks(todayItem, tomorrowItem, tarifsItem, refreshItem, purgeItem, quitItem)
 
-	// Start midnight scheduler
-	go scheduleMidnightNotification()
+	// Relaie les mises à jour diffusées par le Service (y compris celles
+	// déclenchées via tempoctl refresh) vers le menu et l'icône.
+	go watchUpdates(todayItem, tomorrowItem, tarifsItem, quotaBleuItem, quotaBlancItem, quotaRougeItem)
 
 	logger.Info("Interface système prêt")
 }
 
 func onExit() {
 	logger.Info("Tempo EDF en train de quitter...")
+	if ipcSrv != nil {
+		_ = ipcSrv.Close()
+	}
+	if metricsSrv != nil {
+		_ = metricsSrv.Close()
+	}
 }
 
-func handleMenuClicks(todayItem, tomorrowItem, tarifsItem, refreshItem, quitItem *systray.MenuItem) {
+func handleMenuClicks(todayItem, tomorrowItem, tarifsItem, refreshItem, purgeItem, quitItem *systray.MenuItem) {
 	for {
 		select {
 		case <-todayItem.ClickedCh:
-			sendNotification("Tempo EDF", fmt.Sprintf("Aujourd'hui : %s", currentData.TodayColor), "")
+			sendNotification("Tempo EDF", fmt.Sprintf("Aujourd'hui : %s", svc.CurrentData().TodayColor), "")
 		case <-tomorrowItem.ClickedCh:
-			sendNotification("Tempo EDF", fmt.Sprintf("Demain : %s", currentData.TomorrowColor), "")
+			sendNotification("Tempo EDF", fmt.Sprintf("Demain : %s", svc.CurrentData().TomorrowColor), "")
 		case <-tarifsItem.ClickedCh:
-			sendNotification("Tempo EDF", fmt.Sprintf("Tarif actuel : %.3f€/kWh - %s", currentData.CurrentTarif, currentData.TarifLib), "")
+			data := svc.CurrentData()
+			sendNotification("Tempo EDF", fmt.Sprintf("Tarif actuel : %.3f€/kWh - %s", data.CurrentTarif, data.TarifLib), "")
 		case <-refreshItem.ClickedCh:
-			updateData()
-			updateMenuItems(todayItem, tomorrowItem, tarifsItem)
-			updateIconBasedOnColor() // Mise à jour icône après refresh
+			svc.UpdateData()
+			data := svc.CurrentData()
+			updateIconBasedOnColor()
 			sendNotification("Tempo EDF", fmt.Sprintf("Données mises à jour : %s - Tarif : %.3f€/kWh",
-				currentData.TodayColor, currentData.CurrentTarif), "")
+				data.TodayColor, data.CurrentTarif), "")
+		case <-purgeItem.ClickedCh:
+			if err := svc.Purge(); err != nil {
+				logger.Error("Erreur purge du cache", "error", err)
+				sendNotification("Tempo EDF", "Erreur lors de la purge du cache", "")
+			} else {
+				sendNotification("Tempo EDF", "Cache purgé", "")
+			}
 		case <-quitItem.ClickedCh:
 			systray.Quit()
-		case <-startupItem.ClickedCh: // Gestion du checkbox Windows
+		case <-startupItem.ClickedCh: // Gestion du checkbox de démarrage automatique
 			if startupItem.Checked() {
-				if err := removeFromStartup(); err != nil {
+				if err := startup.Disable(); err != nil {
 					logger.Error("Erreur suppression démarrage", "error", err)
 					sendNotification("Tempo EDF", "Erreur lors de la suppression du démarrage", "")
 				} else {
@@ -256,7 +320,7 @@ func handleMenuClicks(todayItem, tomorrowItem, tarifsItem, refreshItem, quitItem
 					sendNotification("Tempo EDF", "Application supprimée du démarrage", "")
 				}
 			} else {
-				if err := addToStartup(); err != nil {
+				if err := startup.Enable(exePath); err != nil {
 					logger.Error("Erreur ajout démarrage", "error", err)
 					sendNotification("Tempo EDF", "Erreur lors de l'ajout au démarrage", "")
 				} else {
@@ -268,190 +332,168 @@ func handleMenuClicks(todayItem, tomorrowItem, tarifsItem, refreshItem, quitItem
 	}
 }
 
-func updateMenuItems(todayItem, tomorrowItem, tarifsItem *systray.MenuItem) {
-	todayItem.SetTitle(fmt.Sprintf("Aujourd'hui : %s", currentData.TodayColor))
-	tomorrowItem.SetTitle(fmt.Sprintf("Demain : %s", currentData.TomorrowColor))
-	tarifsItem.SetTitle(fmt.Sprintf("Tarif actuel : %.3f€/kWh", currentData.CurrentTarif))
+// watchUpdates s'abonne au Service et tient le menu et l'icône à jour,
+// que la mise à jour vienne du scheduler de minuit ou d'un `tempoctl refresh`.
+func watchUpdates(todayItem, tomorrowItem, tarifsItem, quotaBleuItem, quotaBlancItem, quotaRougeItem *systray.MenuItem) {
+	ch := svc.Subscribe()
+	defer svc.Unsubscribe(ch)
+	for range ch {
+		updateMenuItems(todayItem, tomorrowItem, tarifsItem)
+		updateQuotaMenuItems(quotaBleuItem, quotaBlancItem, quotaRougeItem)
+		updateIconBasedOnColor()
+	}
 }
 
-func updateData() {
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	go func() {
-		defer wg.Done()
-		updateColors()
-	}()
-
-	go func() {
-		defer wg.Done()
-		updateCurrentTarif()
-	}()
+func updateMenuItems(todayItem, tomorrowItem, tarifsItem *systray.MenuItem) {
+	data := svc.CurrentData()
+	todayItem.SetTitle(fmt.Sprintf("Aujourd'hui : %s", data.TodayColor))
+	tomorrowItem.SetTitle(fmt.Sprintf("Demain : %s", data.TomorrowColor))
+	tarifsItem.SetTitle(fmt.Sprintf("Tarif actuel : %.3f€/kWh", data.CurrentTarif))
+}
 
-	wg.Wait()
+// getCurrentCfg retourne une copie de la configuration courante.
+func getCurrentCfg() config.Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return currentCfg
 }
 
-func codeToColor(code int) string {
-	switch code {
-	case 1:
-		return "BLEU"
-	case 2:
-		return "BLANC"
-	case 3:
-		return "ROUGE"
-	default:
-		return "INCONNU"
+// refreshMenuStrings réapplique les libellés de menu qui dépendent de la
+// configuration, pour refléter un rechargement à chaud sans redémarrer le
+// tray.
+func refreshMenuStrings() {
+	cfg := getCurrentCfg()
+	if refreshItem != nil {
+		refreshItem.SetTooltip(fmt.Sprintf("Rafraîchir les données (cache: %s)", cfg.CacheTTL.Get()))
+	}
+	if purgeItem != nil {
+		purgeItem.SetTooltip(fmt.Sprintf("Vider le cache mémoire et disque (timeout HTTP: %s)", cfg.Timeout.Get()))
 	}
 }
 
-func updateColors() {
-	dataMaj.Lock()
-	defer dataMaj.Unlock()
-
-	logger.Debug("Récupération de la couleur d'aujourd'hui")
-
-	today, err := fetch[TempoResponse](fmt.Sprintf("%s/jourTempo/today", defaultAPIURL))
-	if err != nil {
-		logger.Error("Erreur récupération couleur aujourd'hui", "error", err)
-		currentData.TodayColor = "ERREUR"
+// updateQuotaMenuItems rafraîchit les trois lignes de quota saisonnier,
+// notamment après un changement de jour ou un backfill.
+func updateQuotaMenuItems(quotaBleuItem, quotaBlancItem, quotaRougeItem *systray.MenuItem) {
+	if quotaTracker == nil || quotaBleuItem == nil {
 		return
 	}
+	counts := quotaTracker.Counts()
+	quotaBleuItem.SetTitle(quota.FormatLine("BLEU", counts.Bleu, quota.Caps.Bleu, 20))
+	quotaBlancItem.SetTitle(quota.FormatLine("BLANC", counts.Blanc, quota.Caps.Blanc, 20))
+	quotaRougeItem.SetTitle(quota.FormatLine("ROUGE", counts.Rouge, quota.Caps.Rouge, 20))
+}
 
-	currentData.TodayColor = codeToColor(today.CodeJour)
-	logger.Info("Couleur d'aujourd'hui", "color", currentData.TodayColor)
-
-	logger.Debug("Récupération de la couleur de demain")
+func sendNotification(title, message, appIcon string) {
+	logger.Debug("Envoi notification", "title", title, "message", message)
 
-	tomorrow, err := fetch[TempoResponse](fmt.Sprintf("%s/jourTempo/tomorrow", defaultAPIURL))
+	err := beeep.Notify(title, message, appIcon)
 	if err != nil {
-		logger.Error("Erreur récupération couleur demain", "error", err)
-		currentData.TomorrowColor = "ERREUR"
-		return
+		logger.Error("Erreur notification", "error", err)
+	} else {
+		logger.Info("Notification envoyée", "title", title)
 	}
-
-	currentData.TomorrowColor = codeToColor(tomorrow.CodeJour)
-	logger.Info("Couleur de demain", "color", currentData.TomorrowColor)
 }
 
-func updateCurrentTarif() {
-	dataMaj.Lock()
-	defer dataMaj.Unlock()
+func scheduleMidnightNotification() {
+	for {
+		now := time.Now()
+		nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+		duration := nextMidnight.Sub(now)
+		logger.Debug("Attente jusqu'au prochain minuit", "duration", duration)
+		time.Sleep(duration)
 
-	logger.Debug("Récupération du tarif actuel")
+		// Le jour qui vient de s'achever est celui qui était affiché comme
+		// "aujourd'hui" juste avant minuit.
+		endingDay := svc.CurrentData()
+		endedDate := nextMidnight.AddDate(0, 0, -1).Format("2006-01-02")
+		if quotaTracker != nil {
+			if err := quotaTracker.RecordDay(endedDate, endingDay.TodayColor); err != nil {
+				logger.Warn("Erreur comptabilisation quota saisonnier", "error", err)
+			}
+		}
 
-	now, err := fetch[NowResponse](fmt.Sprintf("%s/now", defaultAPIURL))
-	if err != nil {
-		logger.Error("Erreur récupération tarif", "error", err)
-		currentData.CurrentTarif = 0
-		currentData.TarifLib = "Erreur"
-		return
+		svc.UpdateData()
+		data := svc.CurrentData()
+		notifyDayChange(endingDay.TodayColor, data)
 	}
-
-	currentData.CurrentTarif = now.TarifKwh
-	currentData.TarifLib = now.LibTarif
-	logger.Info("Tarif actuel", "tarif", now.TarifKwh, "libelle", now.LibTarif)
-}
-
-// cacheEntry représente une entrée de cache avec sa durée de validité
-type cacheEntry struct {
-	data    []byte
-	expires time.Time
 }
 
-// fetch utilise le cache si disponible, sinon fait une requête HTTP
-func fetch[T any](url string) (T, error) {
-	// Check cache first
-	cacheMu.RLock()
-	entry, exists := cache[url]
-	if exists && time.Now().Before(entry.expires) {
-		logger.Debug("Cache hit", "url", url)
-		data := entry.data
-		cacheMu.RUnlock()
-		var result T
-		if err := json.Unmarshal(data, &result); err != nil {
-			logger.Error("Erreur parsing cache", "error", err)
-			var zero T
-			return zero, err
-		}
-		return result, nil
+// notifyDayChange envoie la notification de changement de jour en tenant
+// compte des préférences utilisateur (plage silencieuse, notification
+// uniquement au changement de couleur ou uniquement les jours ROUGE).
+func notifyDayChange(previousColor string, data tempo.Data) {
+	cfg := getCurrentCfg()
+	if inSilentWindow(cfg.Notifications, time.Now()) {
+		logger.Debug("Notification de changement de jour ignorée (plage silencieuse)")
+		return
 	}
-	cacheMu.RUnlock()
-
-	logger.Debug("Requête HTTP", "url", url)
-
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		logger.Error("Erreur HTTP", "url", url, "error", err)
-		var zero T
-		return zero, err
+	if cfg.Notifications.RougeOnly && data.TodayColor != "ROUGE" {
+		return
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		logger.Error("Erreur statut HTTP", "url", url, "status", resp.StatusCode)
-		var zero T
-		return zero, errors.New("unexpected status code")
+	if cfg.Notifications.ColorChangeOnly && data.TodayColor == previousColor {
+		return
 	}
+	sendNotification("Tempo EDF", fmt.Sprintf("Nouveau jour : %s - Tarif : %.3f€/kWh", data.TodayColor, data.CurrentTarif), "")
+	notifyUpcomingRouge(data)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Error("Erreur lecture réponse", "error", err)
-		var zero T
-		return zero, err
+// inSilentWindow indique si now tombe dans la plage silencieuse "HH:MM"-
+// "HH:MM" configurée. Une plage vide désactive la fonctionnalité. La plage
+// peut chevaucher minuit (ex: 22:00-07:00).
+func inSilentWindow(cfg config.Notifications, now time.Time) bool {
+	if cfg.SilentStart == "" || cfg.SilentEnd == "" {
+		return false
 	}
-
-	var result T
-	if err = json.Unmarshal(body, &result); err != nil {
-		logger.Error("Erreur parsing JSON", "error", err)
-		var zero T
-		return zero, err
+	start, err := time.ParseInLocation("15:04", cfg.SilentStart, now.Location())
+	if err != nil {
+		return false
 	}
-
-	// Cache the result (only if no error)
-	cacheMu.Lock()
-	cache[url] = &cacheEntry{
-		data:    body,
-		expires: time.Now().Add(config.CacheTTL),
+	end, err := time.ParseInLocation("15:04", cfg.SilentEnd, now.Location())
+	if err != nil {
+		return false
 	}
-	cacheMu.Unlock()
 
-	return result, nil
-}
-
-func sendNotification(title, message, appIcon string) {
-	logger.Debug("Envoi notification", "title", title, "message", message)
+	nowMinutes := now.Hour()*60 + now.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
 
-	err := beeep.Notify(title, message, appIcon)
-	if err != nil {
-		logger.Error("Erreur notification", "error", err)
-	} else {
-		logger.Info("Notification envoyée", "title", title)
+	if startMinutes <= endMinutes {
+		return nowMinutes >= startMinutes && nowMinutes < endMinutes
 	}
+	// La plage chevauche minuit.
+	return nowMinutes >= startMinutes || nowMinutes < endMinutes
 }
 
-func scheduleMidnightNotification() {
-	for {
-		now := time.Now()
-		nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
-		duration := nextMidnight.Sub(now)
-		logger.Debug("Attente jusqu'au prochain minuit", "duration", duration)
-		time.Sleep(duration)
-		updateData()
-		updateIconBasedOnColor()
-		sendNotification("Tempo EDF", fmt.Sprintf("Nouveau jour : %s - Tarif : %.3f€/kWh", currentData.TodayColor, currentData.CurrentTarif), "")
+// notifyUpcomingRouge prévient l'utilisateur lorsque demain est un jour
+// ROUGE, avec le nombre de jours ROUGE restants dans le quota saisonnier,
+// pour qu'il puisse anticiper sa consommation.
+func notifyUpcomingRouge(data tempo.Data) {
+	if data.TomorrowColor != "ROUGE" || quotaTracker == nil {
+		return
 	}
+	counts := quotaTracker.Counts()
+	remaining := quota.Caps.Rouge - counts.Rouge
+	sendNotification("Tempo EDF - Jour ROUGE demain",
+		fmt.Sprintf("Demain est un jour ROUGE (%d/%d utilisés cette saison, %d restants)", counts.Rouge, quota.Caps.Rouge, remaining), "")
 }
 
 // updateIconBasedOnColor met à jour l'icône sans relancer l'application
 func updateIconBasedOnColor() {
-	icon := loadIcon(currentData.TodayColor)
+	data := svc.CurrentData()
+	icon := loadIcon(data.TodayColor)
 	if len(icon) > 0 {
 		systray.SetIcon(icon)
-		logger.Info("Icône mise à jour", "color", currentData.TodayColor, "size", len(icon))
+		logger.Info("Icône mise à jour", "color", data.TodayColor, "size", len(icon), "stale", data.Stale)
 	} else {
 		logger.Warn("Icône non définie (données vides ou fichier manquant) - Utilisation fallback")
 		systray.SetIcon(icnBlanc) // Fallback forcé
 	}
+
+	if data.Stale {
+		systray.SetTooltip("Tempo EDF - données en cache (hors-ligne)")
+	} else {
+		systray.SetTooltip("Tempo EDF - Couleur du jour")
+	}
 }
 
 // loadIcon charge l'icône correspondant à la couleur Tempo actuelle
@@ -469,31 +511,69 @@ func loadIcon(colorName string) []byte {
 		return icnBlanc // Fallback explicite pour erreurs
 	}
 
-	// Fallback: charge depuis les fichiers (ajusté par OS)
-	var ext string
-	switch runtime.GOOS {
-	case "windows":
-		ext = ".ico"
-	case "darwin":
-		ext = ".png"
-	default:
-		ext = ".png"
+	// Fallback: charge depuis les fichiers, thème configuré d'abord
+	if themeDir != "" {
+		if data, err := findIconFile(themeDir, colorKey); err == nil {
+			return data
+		}
+	}
+	if data, err := findIconFile(appDir, colorKey); err == nil {
+		return data
+	}
+
+	// Fallback par défaut: icône blanche
+	logger.Warn("Aucune icône trouvée pour couleur", "color", colorName)
+	return icnBlanc
+}
+
+// iconExt retourne l'extension d'icône adaptée à l'OS courant.
+func iconExt() string {
+	if runtime.GOOS == "windows" {
+		return ".ico"
 	}
-	var iconPaths = []string{
+	return ".png"
+}
+
+// findIconFile cherche l'icône de colorKey sous dir/assets, en essayant les
+// variantes de nommage utilisées par nos assets (minuscule, capitalisée,
+// préfixée "icon_").
+func findIconFile(dir, colorKey string) ([]byte, error) {
+	ext := iconExt()
+	candidates := []string{
 		fmt.Sprintf("assets/%s%s", colorKey, ext),
 		fmt.Sprintf("assets/%s%s", strings.Title(colorKey), ext),
 		fmt.Sprintf("assets/icon_%s%s", colorKey, ext),
 	}
-
-	for _, relPath := range iconPaths {
-		path := filepath.Join(appDir, relPath)
+	for _, relPath := range candidates {
+		path := filepath.Join(dir, relPath)
 		data, err := os.ReadFile(path)
 		if err == nil && len(data) > 100 {
-			return data
+			return data, nil
 		}
 	}
+	return nil, fmt.Errorf("aucune icône %s trouvée sous %s", colorKey, dir)
+}
+
+// applyIconTheme recharge icnBlanc/icnRouge/icnBleu depuis cfg.Icons.ThemeDir
+// si renseigné, pour permettre d'installer un thème d'icônes alternatif sans
+// toucher aux assets installés. Un thème incomplet ou illisible est ignoré
+// avec un message clair: les icônes par défaut chargées par init restent en
+// place plutôt que de planter l'application.
+func applyIconTheme(cfg config.Config) {
+	if cfg.Icons.ThemeDir == "" {
+		themeDir = ""
+		return
+	}
 
-	// Fallback par défaut: icône blanche
-	logger.Warn("Aucune icône trouvée pour couleur", "color", colorName)
-	return icnBlanc
-}
\ No newline at end of file
+	blanc, errBlanc := findIconFile(cfg.Icons.ThemeDir, "blanc")
+	rouge, errRouge := findIconFile(cfg.Icons.ThemeDir, "rouge")
+	bleu, errBleu := findIconFile(cfg.Icons.ThemeDir, "bleu")
+	if errBlanc != nil || errRouge != nil || errBleu != nil {
+		logger.Warn("Thème d'icônes incomplet, conservation du thème par défaut", "themeDir", cfg.Icons.ThemeDir)
+		return
+	}
+
+	themeDir = cfg.Icons.ThemeDir
+	icnBlanc, icnRouge, icnBleu = blanc, rouge, bleu
+	logger.Info("Thème d'icônes chargé", "themeDir", themeDir)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"tempo-edf/config"
+)
+
+func TestInSilentWindow(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  config.Notifications
+		now  time.Time
+		want bool
+	}{
+		{
+			name: "plage désactivée (valeurs vides)",
+			cfg:  config.Notifications{},
+			now:  time.Date(2026, 7, 29, 23, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "plage normale, dans la fenêtre",
+			cfg:  config.Notifications{SilentStart: "13:00", SilentEnd: "14:00"},
+			now:  time.Date(2026, 7, 29, 13, 30, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "plage normale, hors fenêtre",
+			cfg:  config.Notifications{SilentStart: "13:00", SilentEnd: "14:00"},
+			now:  time.Date(2026, 7, 29, 15, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "plage chevauchant minuit, avant minuit",
+			cfg:  config.Notifications{SilentStart: "22:00", SilentEnd: "07:00"},
+			now:  time.Date(2026, 7, 29, 23, 30, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "plage chevauchant minuit, après minuit",
+			cfg:  config.Notifications{SilentStart: "22:00", SilentEnd: "07:00"},
+			now:  time.Date(2026, 7, 29, 3, 0, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "plage chevauchant minuit, hors fenêtre en journée",
+			cfg:  config.Notifications{SilentStart: "22:00", SilentEnd: "07:00"},
+			now:  time.Date(2026, 7, 29, 12, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "borne de fin exclue",
+			cfg:  config.Notifications{SilentStart: "13:00", SilentEnd: "14:00"},
+			now:  time.Date(2026, 7, 29, 14, 0, 0, 0, time.UTC),
+			want: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := inSilentWindow(c.cfg, c.now); got != c.want {
+				t.Errorf("inSilentWindow(%+v, %v) = %v, want %v", c.cfg, c.now, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package metrics expose l'état du service Tempo au format d'exposition
+// Prometheus/OpenMetrics, pour permettre à Home Assistant, Grafana ou
+// Node-RED de déclencher des automatisations (recharger le véhicule la
+// nuit, préchauffer avant un jour ROUGE) sans dupliquer les requêtes vers
+// l'API Tempo. Désactivé par défaut, activé via features.metrics_port.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"tempo-edf/quota"
+	"tempo-edf/tempo"
+)
+
+// tempoColors énumère les valeurs possibles des jauges *_color, exposées
+// sous forme d'un gauge par valeur (1 pour la couleur active, 0 sinon),
+// seule forme représentable d'une énumération en Prometheus.
+var tempoColors = []string{"BLEU", "BLANC", "ROUGE"}
+
+// Exporter sert /metrics pour un *tempo.Service, et, si présent, un
+// *quota.Tracker. Il lit l'état courant à chaque scrape (modèle pull) sans
+// dupliquer de compteurs: les totaux viennent de tempo.Service.Stats.
+type Exporter struct {
+	addr   string
+	svc    *tempo.Service
+	quota  *quota.Tracker
+	logger *slog.Logger
+	srv    *http.Server
+}
+
+// NewExporter construit un exporteur qui écoutera sur addr (ex: ":9123").
+// quotaTracker peut être nil si le suivi saisonnier est désactivé.
+func NewExporter(addr string, svc *tempo.Service, quotaTracker *quota.Tracker, logger *slog.Logger) *Exporter {
+	e := &Exporter{addr: addr, svc: svc, quota: quotaTracker, logger: logger}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", e.handleMetrics)
+	e.srv = &http.Server{Addr: addr, Handler: mux}
+	return e
+}
+
+// Serve démarre le serveur HTTP et bloque jusqu'à Close ou une erreur.
+func (e *Exporter) Serve() error {
+	e.logger.Info("Exporteur de métriques démarré", "addr", e.addr)
+	if err := e.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Close arrête le serveur HTTP.
+func (e *Exporter) Close() error {
+	return e.srv.Close()
+}
+
+func (e *Exporter) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	data := e.svc.CurrentData()
+	stats := e.svc.Stats()
+
+	writeGauge(w, "tempo_edf_current_tariff_eur_kwh", "Tarif Tempo actuellement applicable, en euros par kWh.", data.CurrentTarif)
+
+	writeColorGauges(w, "tempo_edf_today_color", "Couleur Tempo du jour (1 pour la couleur active, 0 sinon).", data.TodayColor)
+	writeColorGauges(w, "tempo_edf_tomorrow_color", "Couleur Tempo de demain (1 pour la couleur active, 0 sinon).", data.TomorrowColor)
+
+	writeCounter(w, "tempo_edf_cache_hits_total", "Nombre de requêtes servies depuis le cache mémoire.", float64(stats.CacheHits))
+	writeCounter(w, "tempo_edf_cache_misses_total", "Nombre de requêtes ayant nécessité un appel HTTP.", float64(stats.CacheMisses))
+	writeCounter(w, "tempo_edf_api_errors_total", "Nombre d'échecs de requête vers l'API Tempo.", float64(stats.APIErrors))
+
+	writeGauge(w, "tempo_edf_last_update_timestamp_seconds", "Horodatage Unix de la dernière mise à jour des données, réussie ou non.", float64(stats.LastUpdated.Unix()))
+
+	if e.quota != nil {
+		writeQuotaGauges(w, "tempo_edf_quota_days_used", "Jours Tempo comptabilisés depuis le début de la saison, par couleur.", e.quota.Counts())
+		writeQuotaGauges(w, "tempo_edf_quota_days_cap", "Quota annuel de jours Tempo publié par EDF, par couleur.", quota.Caps)
+	}
+}
+
+func writeGauge(w io.Writer, name, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", name, help, name, name, value)
+}
+
+func writeCounter(w io.Writer, name, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %v\n", name, help, name, name, value)
+}
+
+func writeColorGauges(w io.Writer, name, help, active string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	for _, color := range tempoColors {
+		value := 0
+		if color == active {
+			value = 1
+		}
+		fmt.Fprintf(w, "%s{color=%q} %d\n", name, color, value)
+	}
+}
+
+// writeQuotaGauges émet une jauge par couleur à partir de counts (jours
+// utilisés ou quota maximal, selon l'appelant).
+func writeQuotaGauges(w io.Writer, name, help string, counts quota.Counts) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	fmt.Fprintf(w, "%s{color=\"BLEU\"} %d\n", name, counts.Bleu)
+	fmt.Fprintf(w, "%s{color=\"BLANC\"} %d\n", name, counts.Blanc)
+	fmt.Fprintf(w, "%s{color=\"ROUGE\"} %d\n", name, counts.Rouge)
+}
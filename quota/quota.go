@@ -0,0 +1,230 @@
+// Package quota suit la consommation des jours Tempo BLEU/BLANC/ROUGE sur
+// une saison (1er septembre au 31 août, heure de Paris) et la compare aux
+// quotas publiés par EDF.
+package quota
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// paris est le fuseau horaire de référence pour les bornes de saison.
+var paris = mustLoadParis()
+
+func mustLoadParis() *time.Location {
+	loc, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Counts dénombre les jours déjà écoulés pour chaque couleur Tempo.
+type Counts struct {
+	Bleu  int `json:"bleu"`
+	Blanc int `json:"blanc"`
+	Rouge int `json:"rouge"`
+}
+
+// Caps sont les quotas annuels publiés par EDF pour l'offre Tempo.
+var Caps = Counts{Bleu: 300, Blanc: 43, Rouge: 22}
+
+// dateLayout est le format yyyy-mm-dd utilisé par l'API jourTempo.
+const dateLayout = "2006-01-02"
+
+// state est la forme persistée de season.json.
+type state struct {
+	SeasonStart string `json:"seasonStart"` // yyyy-mm-dd
+	LastDay     string `json:"lastDay"`     // yyyy-mm-dd, dernier jour compté
+	Counts      Counts `json:"counts"`
+}
+
+// Tracker suit les compteurs de la saison en cours et les persiste dans un
+// fichier JSON. seasonStart/lastDay/counts sont lus et modifiés depuis
+// plusieurs goroutines (backfill au démarrage, scheduler de minuit, menu
+// systray, scrape /metrics) : mu les protège.
+type Tracker struct {
+	path   string
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	seasonStart string
+	lastDay     string
+	counts      Counts
+}
+
+// NewTracker charge (ou initialise) le suivi de quota depuis path.
+func NewTracker(path string, logger *slog.Logger) *Tracker {
+	t := &Tracker{path: path, logger: logger}
+
+	season := SeasonStart(time.Now().In(paris)).Format(dateLayout)
+
+	st, err := loadState(path)
+	if err != nil {
+		logger.Warn("Erreur lecture season.json, réinitialisation", "error", err)
+	}
+	if err == nil && st.SeasonStart == season {
+		t.seasonStart = st.SeasonStart
+		t.lastDay = st.LastDay
+		t.counts = st.Counts
+		return t
+	}
+
+	// Nouvelle saison, ou fichier absent/invalide: on repart de zéro.
+	t.seasonStart = season
+	t.lastDay = ""
+	t.counts = Counts{}
+	return t
+}
+
+// SeasonStart retourne le 1er septembre (minuit, heure de Paris) de la
+// saison Tempo à laquelle appartient t : la saison bascule le 1er septembre.
+func SeasonStart(t time.Time) time.Time {
+	t = t.In(paris)
+	year := t.Year()
+	if t.Month() < time.September {
+		year--
+	}
+	return time.Date(year, time.September, 1, 0, 0, 0, 0, paris)
+}
+
+func loadState(path string) (state, error) {
+	var st state
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return st, nil
+}
+
+func (t *Tracker) save() error {
+	t.mu.Lock()
+	st := state{SeasonStart: t.seasonStart, LastDay: t.lastDay, Counts: t.counts}
+	t.mu.Unlock()
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encodage season.json: %w", err)
+	}
+	if err := os.WriteFile(t.path, data, 0644); err != nil {
+		return fmt.Errorf("écriture %s: %w", t.path, err)
+	}
+	return nil
+}
+
+// Counts retourne une copie des compteurs de la saison en cours.
+func (t *Tracker) Counts() Counts {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts
+}
+
+// RecordDay comptabilise day (format yyyy-mm-dd) avec la couleur donnée, et
+// persiste le résultat. Un jour déjà comptabilisé (day <= lastDay) est
+// ignoré pour rester idempotent face aux redémarrages. Si day appartient à
+// une nouvelle saison Tempo (1er septembre), les compteurs sont d'abord
+// remis à zéro : contrairement à NewTracker, un daemon qui tourne en continu
+// ne relit jamais season.json et ne verrait sinon jamais le changement de
+// saison.
+func (t *Tracker) RecordDay(day, color string) error {
+	t.mu.Lock()
+
+	if t.lastDay != "" && day <= t.lastDay {
+		t.mu.Unlock()
+		return nil
+	}
+
+	if parsed, err := time.ParseInLocation(dateLayout, day, paris); err == nil {
+		if season := SeasonStart(parsed).Format(dateLayout); season != t.seasonStart {
+			t.logger.Info("Nouvelle saison Tempo, remise à zéro des compteurs", "previousSeason", t.seasonStart, "season", season)
+			t.seasonStart = season
+			t.lastDay = ""
+			t.counts = Counts{}
+		}
+	}
+
+	switch color {
+	case "BLEU":
+		t.counts.Bleu++
+	case "BLANC":
+		t.counts.Blanc++
+	case "ROUGE":
+		t.counts.Rouge++
+	default:
+		t.logger.Warn("Couleur inconnue pour le suivi de quota", "day", day, "color", color)
+		t.mu.Unlock()
+		return nil
+	}
+
+	t.lastDay = day
+	t.mu.Unlock()
+	return t.save()
+}
+
+// Backfill comble les jours manquants entre le dernier jour connu (exclu) et
+// hier (inclus), en utilisant fetchColor pour interroger /jourTempo/{date}
+// via le cache existant. today doit être l'heure courante.
+func (t *Tracker) Backfill(today time.Time, fetchColor func(date string) (string, error)) error {
+	yesterday := today.In(paris).AddDate(0, 0, -1)
+
+	t.mu.Lock()
+	lastDay := t.lastDay
+	t.mu.Unlock()
+
+	start := SeasonStart(today)
+	if lastDay != "" {
+		last, err := time.ParseInLocation(dateLayout, lastDay, paris)
+		if err == nil && last.After(start) {
+			start = last.AddDate(0, 0, 1)
+		}
+	}
+
+	for d := start; !d.After(yesterday); d = d.AddDate(0, 0, 1) {
+		day := d.Format(dateLayout)
+		color, err := fetchColor(day)
+		if err != nil {
+			t.logger.Warn("Backfill quota: échec récupération jour", "day", day, "error", err)
+			continue
+		}
+		if err := t.RecordDay(day, color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bar produit une barre ASCII de largeur cols représentant used/total,
+// composée de runes "pleines" puis "vides".
+func Bar(used, total, cols int) string {
+	if total <= 0 || cols <= 0 {
+		return ""
+	}
+	usedBars := int(math.Round(float64(used) / float64(total) * float64(cols)))
+	if usedBars > cols {
+		usedBars = cols
+	}
+	remainingBars := cols - usedBars
+
+	full := make([]rune, usedBars)
+	for i := range full {
+		full[i] = '█'
+	}
+	empty := make([]rune, remainingBars)
+	for i := range empty {
+		empty[i] = '░'
+	}
+	return string(full) + string(empty)
+}
+
+// FormatLine rend une ligne de menu du type "BLEU 187/300 [██████░░░░]".
+func FormatLine(label string, used, total, cols int) string {
+	return fmt.Sprintf("%s %d/%d [%s]", label, used, total, Bar(used, total, cols))
+}
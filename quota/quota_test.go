@@ -0,0 +1,112 @@
+package quota
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestSeasonStart(t *testing.T) {
+	cases := []struct {
+		name string
+		in   time.Time
+		want time.Time
+	}{
+		{
+			name: "milieu de saison",
+			in:   time.Date(2026, time.January, 15, 12, 0, 0, 0, paris),
+			want: time.Date(2025, time.September, 1, 0, 0, 0, 0, paris),
+		},
+		{
+			name: "jour de bascule, 1er septembre",
+			in:   time.Date(2026, time.September, 1, 0, 0, 0, 0, paris),
+			want: time.Date(2026, time.September, 1, 0, 0, 0, 0, paris),
+		},
+		{
+			name: "veille de bascule, 31 août",
+			in:   time.Date(2026, time.August, 31, 23, 59, 59, 0, paris),
+			want: time.Date(2025, time.September, 1, 0, 0, 0, 0, paris),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := SeasonStart(c.in); !got.Equal(c.want) {
+				t.Errorf("SeasonStart(%v) = %v, want %v", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestBar(t *testing.T) {
+	cases := []struct {
+		used, total, cols int
+		want              string
+	}{
+		{used: 0, total: 10, cols: 10, want: "░░░░░░░░░░"},
+		{used: 10, total: 10, cols: 10, want: "██████████"},
+		{used: 5, total: 10, cols: 10, want: "█████░░░░░"},
+		{used: 20, total: 10, cols: 10, want: "██████████"}, // dépassement du total: plafonné
+		{used: 1, total: 0, cols: 10, want: ""},             // total invalide
+		{used: 1, total: 10, cols: 0, want: ""},             // cols invalide
+		{used: 2, total: 3, cols: 10, want: "███████░░░"},   // 6.67 arrondi à 7, pas tronqué à 6
+	}
+	for _, c := range cases {
+		if got := Bar(c.used, c.total, c.cols); got != c.want {
+			t.Errorf("Bar(%d, %d, %d) = %q, want %q", c.used, c.total, c.cols, got, c.want)
+		}
+	}
+}
+
+func TestFormatLine(t *testing.T) {
+	got := FormatLine("BLEU", 5, 10, 10)
+	want := "BLEU 5/10 [█████░░░░░]"
+	if got != want {
+		t.Errorf("FormatLine = %q, want %q", got, want)
+	}
+}
+
+func TestRecordDayIdempotent(t *testing.T) {
+	tr := &Tracker{path: filepath.Join(t.TempDir(), "season.json"), logger: testLogger(), seasonStart: "2025-09-01"}
+
+	if err := tr.RecordDay("2026-01-10", "ROUGE"); err != nil {
+		t.Fatalf("premier RecordDay: %v", err)
+	}
+	if err := tr.RecordDay("2026-01-10", "ROUGE"); err != nil {
+		t.Fatalf("second RecordDay (même jour): %v", err)
+	}
+	if err := tr.RecordDay("2026-01-05", "ROUGE"); err != nil { // jour antérieur, doit être ignoré
+		t.Fatalf("RecordDay jour antérieur: %v", err)
+	}
+
+	if got := tr.Counts().Rouge; got != 1 {
+		t.Errorf("Counts().Rouge = %d, want 1 (RecordDay doit être idempotent)", got)
+	}
+}
+
+func TestRecordDaySeasonRollover(t *testing.T) {
+	tr := &Tracker{
+		path:        filepath.Join(t.TempDir(), "season.json"),
+		logger:      testLogger(),
+		seasonStart: "2025-09-01",
+		lastDay:     "2026-08-31",
+		counts:      Counts{Bleu: 290, Blanc: 40, Rouge: 20},
+	}
+
+	if err := tr.RecordDay("2026-09-01", "BLEU"); err != nil {
+		t.Fatalf("RecordDay au changement de saison: %v", err)
+	}
+
+	if tr.seasonStart != "2026-09-01" {
+		t.Errorf("seasonStart = %q, want 2026-09-01 après la bascule", tr.seasonStart)
+	}
+	want := Counts{Bleu: 1}
+	if tr.Counts() != want {
+		t.Errorf("Counts() = %+v, want %+v (remise à zéro attendue à la nouvelle saison)", tr.Counts(), want)
+	}
+}
@@ -0,0 +1,20 @@
+// Package startup gère l'inscription de tempo-edf au démarrage de la
+// session utilisateur, avec une implémentation par OS : registre Windows,
+// autostart XDG sur Linux, agent launchd sur macOS.
+package startup
+
+// IsEnabled indique si tempo-edf est actuellement configuré pour démarrer
+// avec la session utilisateur.
+func IsEnabled(exePath string) bool {
+	return isEnabled(exePath)
+}
+
+// Enable inscrit exePath au démarrage de la session utilisateur.
+func Enable(exePath string) error {
+	return enable(exePath)
+}
+
+// Disable retire tempo-edf du démarrage de la session utilisateur.
+func Disable() error {
+	return disable()
+}
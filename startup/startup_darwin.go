@@ -0,0 +1,86 @@
+//go:build darwin
+
+package startup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const launchAgentLabel = "ws.makertronic.tempo-edf"
+
+func plistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("détection répertoire utilisateur: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchAgentLabel+".plist"), nil
+}
+
+// isEnabled vérifie la présence du plist LaunchAgent.
+func isEnabled(exePath string) bool {
+	path, err := plistPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// enable écrit le plist LaunchAgent et le charge avec launchctl.
+func enable(exePath string) error {
+	if exePath == "" {
+		return fmt.Errorf("chemin de l'exécutable non détecté")
+	}
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("création du répertoire LaunchAgents: %w", err)
+	}
+
+	content := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`, launchAgentLabel, exePath)
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("écriture du plist LaunchAgent: %w", err)
+	}
+
+	if err := exec.Command("launchctl", "load", path).Run(); err != nil {
+		return fmt.Errorf("chargement du LaunchAgent: %w", err)
+	}
+	return nil
+}
+
+// disable décharge et supprime le plist LaunchAgent.
+func disable() error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		if err := exec.Command("launchctl", "unload", path).Run(); err != nil {
+			return fmt.Errorf("déchargement du LaunchAgent: %w", err)
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("suppression du plist LaunchAgent: %w", err)
+	}
+	return nil
+}
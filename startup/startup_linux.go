@@ -0,0 +1,65 @@
+//go:build linux
+
+package startup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func desktopFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("détection répertoire utilisateur: %w", err)
+	}
+	return filepath.Join(home, ".config", "autostart", "tempo-edf.desktop"), nil
+}
+
+// isEnabled vérifie la présence du fichier .desktop XDG Autostart.
+func isEnabled(exePath string) bool {
+	path, err := desktopFilePath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// enable écrit un fichier .desktop conforme à la spécification XDG Autostart.
+func enable(exePath string) error {
+	if exePath == "" {
+		return fmt.Errorf("chemin de l'exécutable non détecté")
+	}
+	path, err := desktopFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("création du répertoire autostart: %w", err)
+	}
+
+	content := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Tempo EDF
+Exec=%s
+X-GNOME-Autostart-enabled=true
+`, exePath)
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("écriture du fichier autostart: %w", err)
+	}
+	return nil
+}
+
+// disable supprime le fichier .desktop XDG Autostart.
+func disable() error {
+	path, err := desktopFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("suppression du fichier autostart: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+//go:build windows
+
+package startup
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// isEnabled vérifie si l'application est déjà dans le registre de démarrage Windows.
+func isEnabled(exePath string) bool {
+	if exePath == "" {
+		return false
+	}
+	cmd := exec.Command("reg", "query", `HKCU\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`, "/v", "TempoEDF")
+	if err := cmd.Run(); err != nil {
+		return false
+	}
+	return true
+}
+
+// enable ajoute l'application au démarrage via le registre Windows.
+func enable(exePath string) error {
+	if exePath == "" {
+		return fmt.Errorf("chemin de l'exécutable non détecté")
+	}
+	quotedPath := `"` + exePath + `"`
+	cmd := exec.Command("reg", "add", `HKCU\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`, "/v", "TempoEDF", "/t", "REG_SZ", "/d", quotedPath, "/f")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("échec ajout au démarrage: %w", err)
+	}
+	return nil
+}
+
+// disable supprime l'application du démarrage Windows.
+func disable() error {
+	cmd := exec.Command("reg", "delete", `HKCU\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`, "/v", "TempoEDF", "/f")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("échec suppression du démarrage: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package tempo
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cacheMeta est persisté à côté du corps de la réponse pour reconstruire une
+// cacheEntry au redémarrage.
+type cacheMeta struct {
+	URL       string    `json:"url"`
+	Expires   time.Time `json:"expires"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// diskPaths retourne les chemins du corps (.json) et des métadonnées (.meta)
+// associés à url sous dir, nommés d'après le sha1 de l'URL.
+func diskPaths(dir, url string) (jsonPath, metaPath string) {
+	sum := sha1.Sum([]byte(url))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(dir, name+".json"), filepath.Join(dir, name+".meta")
+}
+
+// writeThroughDiskCache écrit le corps et les métadonnées d'une réponse
+// fraîchement récupérée, puis fait respecter maxEntries en supprimant les
+// entrées les plus anciennes si besoin.
+func writeThroughDiskCache(dir, url string, body []byte, expires, fetchedAt time.Time, maxEntries int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("création répertoire cache %s: %w", dir, err)
+	}
+
+	jsonPath, metaPath := diskPaths(dir, url)
+	if err := os.WriteFile(jsonPath, body, 0644); err != nil {
+		return fmt.Errorf("écriture %s: %w", jsonPath, err)
+	}
+
+	meta, err := json.Marshal(cacheMeta{URL: url, Expires: expires, FetchedAt: fetchedAt})
+	if err != nil {
+		return fmt.Errorf("encodage métadonnées: %w", err)
+	}
+	if err := os.WriteFile(metaPath, meta, 0644); err != nil {
+		return fmt.Errorf("écriture %s: %w", metaPath, err)
+	}
+
+	return enforceCacheLimit(dir, maxEntries)
+}
+
+// hydrateDiskCache charge toutes les entrées valides trouvées sous dir.
+func hydrateDiskCache(dir string) (map[string]*cacheEntry, error) {
+	entries := make(map[string]*cacheEntry)
+
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lecture répertoire cache %s: %w", dir, err)
+	}
+
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".meta") {
+			continue
+		}
+		metaPath := filepath.Join(dir, f.Name())
+		metaBytes, err := os.ReadFile(metaPath)
+		if err != nil {
+			continue
+		}
+		var meta cacheMeta
+		if err := json.Unmarshal(metaBytes, &meta); err != nil {
+			continue
+		}
+
+		jsonPath := strings.TrimSuffix(metaPath, ".meta") + ".json"
+		body, err := os.ReadFile(jsonPath)
+		if err != nil {
+			continue
+		}
+
+		entries[meta.URL] = &cacheEntry{
+			data:      body,
+			expires:   meta.Expires,
+			fetchedAt: meta.FetchedAt,
+		}
+	}
+
+	return entries, nil
+}
+
+// enforceCacheLimit supprime les entrées les plus anciennes (par date de
+// récupération) jusqu'à ce qu'il n'en reste plus que maxEntries.
+func enforceCacheLimit(dir string, maxEntries int) error {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxCacheEntries
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		metaPath  string
+		jsonPath  string
+		fetchedAt time.Time
+	}
+	var metas []entry
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".meta") {
+			continue
+		}
+		metaPath := filepath.Join(dir, f.Name())
+		metaBytes, err := os.ReadFile(metaPath)
+		if err != nil {
+			continue
+		}
+		var meta cacheMeta
+		if err := json.Unmarshal(metaBytes, &meta); err != nil {
+			continue
+		}
+		metas = append(metas, entry{
+			metaPath:  metaPath,
+			jsonPath:  strings.TrimSuffix(metaPath, ".meta") + ".json",
+			fetchedAt: meta.FetchedAt,
+		})
+	}
+
+	if len(metas) <= maxEntries {
+		return nil
+	}
+
+	sort.Slice(metas, func(i, j int) bool { return metas[i].fetchedAt.Before(metas[j].fetchedAt) })
+
+	toRemove := len(metas) - maxEntries
+	for _, e := range metas[:toRemove] {
+		os.Remove(e.metaPath)
+		os.Remove(e.jsonPath)
+	}
+	return nil
+}
+
+// purgeDiskCache supprime l'intégralité du cache disque.
+func purgeDiskCache(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return nil
+}
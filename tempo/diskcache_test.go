@@ -0,0 +1,47 @@
+package tempo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWriteThroughDiskCacheHydrate(t *testing.T) {
+	dir := t.TempDir()
+	url := "https://www.api-couleur-tempo.fr/api/jourTempo/today"
+	body := []byte(`{"dateJour":"2026-07-29","codeJour":1,"periode":"normal"}`)
+	expires := time.Date(2026, time.July, 29, 13, 0, 0, 0, time.UTC)
+	fetchedAt := time.Date(2026, time.July, 29, 12, 0, 0, 0, time.UTC)
+
+	if err := writeThroughDiskCache(dir, url, body, expires, fetchedAt, DefaultMaxCacheEntries); err != nil {
+		t.Fatalf("writeThroughDiskCache: %v", err)
+	}
+
+	entries, err := hydrateDiskCache(dir)
+	if err != nil {
+		t.Fatalf("hydrateDiskCache: %v", err)
+	}
+
+	entry, ok := entries[url]
+	if !ok {
+		t.Fatalf("hydrateDiskCache n'a pas retrouvé l'URL %q", url)
+	}
+	if string(entry.data) != string(body) {
+		t.Errorf("data = %s, want %s", entry.data, body)
+	}
+	if !entry.expires.Equal(expires) {
+		t.Errorf("expires = %v, want %v", entry.expires, expires)
+	}
+	if !entry.fetchedAt.Equal(fetchedAt) {
+		t.Errorf("fetchedAt = %v, want %v", entry.fetchedAt, fetchedAt)
+	}
+}
+
+func TestHydrateDiskCacheMissingDir(t *testing.T) {
+	entries, err := hydrateDiskCache(t.TempDir() + "/does-not-exist")
+	if err != nil {
+		t.Fatalf("hydrateDiskCache sur répertoire absent: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("entries = %v, want vide", entries)
+	}
+}
@@ -0,0 +1,427 @@
+// Package tempo regroupe l'état et la logique métier Tempo EDF (récupération
+// des couleurs du jour, tarif courant, cache HTTP) indépendamment de toute
+// interface utilisateur. Le daemon systray et tempoctl s'appuient tous les
+// deux sur un *Service partagé.
+package tempo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultAPIURL  = "https://www.api-couleur-tempo.fr/api"
+	DefaultTimeout = 10 * time.Second
+)
+
+// DefaultMaxCacheEntries borne le nombre d'entrées du cache disque afin que
+// celui-ci ne croisse pas indéfiniment sur une machine qui tourne des mois.
+const DefaultMaxCacheEntries = 200
+
+// Config contient les configurations du service Tempo.
+type Config struct {
+	APIURL   string
+	Timeout  time.Duration
+	CacheTTL time.Duration
+
+	// CacheDir, si non vide, active le cache persistant sur disque en plus
+	// du cache mémoire: write-through à chaque requête réussie, lecture au
+	// démarrage, et repli en cas de panne réseau.
+	CacheDir string
+	// MaxCacheEntries borne le nombre de fichiers conservés dans CacheDir.
+	// Zéro vaut DefaultMaxCacheEntries.
+	MaxCacheEntries int
+}
+
+// Data stocke les données tempo actuelles.
+type Data struct {
+	CurrentTarif  float64
+	TarifLib      string
+	TodayColor    string
+	TomorrowColor string
+	LastUpdated   time.Time
+	// Stale est vrai lorsque les données proviennent du cache (mémoire ou
+	// disque) suite à un échec de l'API (mode dégradé hors-ligne), pas d'une réponse fraîche.
+	Stale bool
+}
+
+// TempoResponse représente la réponse de l'API Tempo.
+type TempoResponse struct {
+	DateJour string `json:"dateJour"`
+	CodeJour int    `json:"codeJour"`
+	Periode  string `json:"periode"`
+	// LibCouleur absent de l'API, donc non utilisé
+}
+
+// NowResponse représente la réponse de l'API now.
+type NowResponse struct {
+	ApplicableIn int     `json:"applicableIn"`
+	CodeCouleur  int     `json:"codeCouleur"`
+	CodeHoraire  int     `json:"codeHoraire"`
+	TarifKwh     float64 `json:"tarifKwh"`
+	LibTarif     string  `json:"libTarif"`
+}
+
+// Stats expose des compteurs de fonctionnement utiles à l'IPC, au menu et à
+// l'exporteur de métriques.
+type Stats struct {
+	CacheHits   int
+	CacheMisses int
+	APIErrors   int
+	LastUpdated time.Time
+}
+
+// cacheEntry représente une entrée de cache avec sa durée de validité.
+type cacheEntry struct {
+	data      []byte
+	expires   time.Time
+	fetchedAt time.Time
+}
+
+// Service regroupe l'état Tempo (données courantes, cache HTTP) et expose
+// des méthodes typées utilisées par le daemon systray, le mode bar et
+// l'IPC. Il remplace les variables globales historiques de main.go.
+type Service struct {
+	config Config
+	logger *slog.Logger
+
+	clientMu   sync.RWMutex
+	httpClient *http.Client
+
+	dataMu sync.RWMutex
+	data   Data
+
+	cacheMu     sync.RWMutex
+	cache       map[string]*cacheEntry
+	cacheHits   int
+	cacheMisses int
+	apiErrors   int
+
+	subMu sync.Mutex
+	subs  map[chan Data]struct{}
+}
+
+// NewService construit un Service prêt à l'emploi avec la configuration
+// donnée. Si cfg.CacheDir est renseigné, le cache disque existant est
+// immédiatement chargé en mémoire afin que le tray affiche une couleur sans
+// attendre le réseau.
+func NewService(cfg Config, logger *slog.Logger) *Service {
+	if cfg.MaxCacheEntries == 0 {
+		cfg.MaxCacheEntries = DefaultMaxCacheEntries
+	}
+
+	s := &Service{
+		config:     cfg,
+		logger:     logger,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		cache:      make(map[string]*cacheEntry),
+		subs:       make(map[chan Data]struct{}),
+	}
+
+	if cfg.CacheDir != "" {
+		entries, err := hydrateDiskCache(cfg.CacheDir)
+		if err != nil {
+			logger.Warn("Erreur hydratation cache disque", "error", err)
+		} else {
+			s.cache = entries
+			logger.Info("Cache disque chargé", "entries", len(entries))
+		}
+	}
+
+	return s
+}
+
+// SetTimeout ajuste le timeout du client HTTP utilisé pour les requêtes
+// Tempo. Destiné au rechargement à chaud de la configuration: un nouveau
+// *http.Client est posé plutôt que de modifier Timeout en place, pour ne pas
+// entrer en compétition avec les requêtes de fetchHTTP en cours.
+func (s *Service) SetTimeout(d time.Duration) {
+	s.clientMu.Lock()
+	s.httpClient = &http.Client{Timeout: d}
+	s.clientMu.Unlock()
+}
+
+// SetCacheTTL ajuste la durée de validité des entrées mises en cache après
+// cet appel. Destiné au rechargement à chaud de la configuration.
+func (s *Service) SetCacheTTL(d time.Duration) {
+	s.cacheMu.Lock()
+	s.config.CacheTTL = d
+	s.cacheMu.Unlock()
+}
+
+// Purge vide le cache mémoire et le cache disque.
+func (s *Service) Purge() error {
+	s.cacheMu.Lock()
+	s.cache = make(map[string]*cacheEntry)
+	s.cacheMu.Unlock()
+
+	if s.config.CacheDir == "" {
+		return nil
+	}
+	if err := purgeDiskCache(s.config.CacheDir); err != nil {
+		return fmt.Errorf("purge cache disque: %w", err)
+	}
+	s.logger.Info("Cache purgé")
+	return nil
+}
+
+// CurrentData retourne une copie des données actuellement connues.
+func (s *Service) CurrentData() Data {
+	s.dataMu.RLock()
+	defer s.dataMu.RUnlock()
+	return s.data
+}
+
+// Stats retourne les compteurs de cache et la date de dernière mise à jour.
+func (s *Service) Stats() Stats {
+	s.cacheMu.RLock()
+	hits, misses, apiErrors := s.cacheHits, s.cacheMisses, s.apiErrors
+	s.cacheMu.RUnlock()
+	return Stats{
+		CacheHits:   hits,
+		CacheMisses: misses,
+		APIErrors:   apiErrors,
+		LastUpdated: s.CurrentData().LastUpdated,
+	}
+}
+
+// Subscribe enregistre un canal qui recevra les données à chaque appel de
+// UpdateData, que les requêtes aient réussi ou non. Le canal doit être retiré avec Unsubscribe lorsqu'il n'est plus lu.
+func (s *Service) Subscribe() chan Data {
+	ch := make(chan Data, 1)
+	s.subMu.Lock()
+	s.subs[ch] = struct{}{}
+	s.subMu.Unlock()
+	return ch
+}
+
+// Unsubscribe retire un canal précédemment obtenu via Subscribe.
+func (s *Service) Unsubscribe(ch chan Data) {
+	s.subMu.Lock()
+	delete(s.subs, ch)
+	s.subMu.Unlock()
+	close(ch)
+}
+
+// broadcast notifie tous les abonnés des données courantes sans bloquer sur
+// un abonné lent (le canal a une capacité de 1, on laisse tomber sinon).
+func (s *Service) broadcast(d Data) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- d:
+		default:
+		}
+	}
+}
+
+// UpdateData rafraîchit la couleur du jour, celle de demain et le tarif
+// courant en parallèle, puis diffuse le résultat aux abonnés.
+func (s *Service) UpdateData() {
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		s.updateColors()
+	}()
+
+	go func() {
+		defer wg.Done()
+		s.updateCurrentTarif()
+	}()
+
+	wg.Wait()
+
+	s.dataMu.Lock()
+	s.data.LastUpdated = time.Now()
+	d := s.data
+	s.dataMu.Unlock()
+
+	s.broadcast(d)
+}
+
+// ColorForDate retourne la couleur Tempo du jour donné (format yyyy-mm-dd)
+// en passant par le même cache que les autres appels API. Utilisé par le
+// suivi de quota saisonnier pour combler les jours manqués au démarrage.
+func (s *Service) ColorForDate(date string) (string, error) {
+	resp, _, err := fetch[TempoResponse](s, fmt.Sprintf("%s/jourTempo/%s", s.config.APIURL, date))
+	if err != nil {
+		return "", err
+	}
+	return codeToColor(resp.CodeJour), nil
+}
+
+func codeToColor(code int) string {
+	switch code {
+	case 1:
+		return "BLEU"
+	case 2:
+		return "BLANC"
+	case 3:
+		return "ROUGE"
+	default:
+		return "INCONNU"
+	}
+}
+
+func (s *Service) updateColors() {
+	s.logger.Debug("Récupération de la couleur d'aujourd'hui")
+
+	today, staleToday, err := fetch[TempoResponse](s, fmt.Sprintf("%s/jourTempo/today", s.config.APIURL))
+	if err != nil {
+		s.logger.Error("Erreur récupération couleur aujourd'hui", "error", err)
+		s.dataMu.Lock()
+		s.data.TodayColor = "ERREUR"
+		s.dataMu.Unlock()
+		return
+	}
+
+	s.dataMu.Lock()
+	s.data.TodayColor = codeToColor(today.CodeJour)
+	s.data.Stale = staleToday
+	s.dataMu.Unlock()
+	s.logger.Info("Couleur d'aujourd'hui", "color", codeToColor(today.CodeJour), "stale", staleToday)
+
+	s.logger.Debug("Récupération de la couleur de demain")
+
+	tomorrow, staleTomorrow, err := fetch[TempoResponse](s, fmt.Sprintf("%s/jourTempo/tomorrow", s.config.APIURL))
+	if err != nil {
+		s.logger.Error("Erreur récupération couleur demain", "error", err)
+		s.dataMu.Lock()
+		s.data.TomorrowColor = "ERREUR"
+		s.dataMu.Unlock()
+		return
+	}
+
+	s.dataMu.Lock()
+	s.data.TomorrowColor = codeToColor(tomorrow.CodeJour)
+	s.data.Stale = s.data.Stale || staleTomorrow
+	s.dataMu.Unlock()
+	s.logger.Info("Couleur de demain", "color", codeToColor(tomorrow.CodeJour), "stale", staleTomorrow)
+}
+
+func (s *Service) updateCurrentTarif() {
+	s.logger.Debug("Récupération du tarif actuel")
+
+	now, stale, err := fetch[NowResponse](s, fmt.Sprintf("%s/now", s.config.APIURL))
+	if err != nil {
+		s.logger.Error("Erreur récupération tarif", "error", err)
+		s.dataMu.Lock()
+		s.data.CurrentTarif = 0
+		s.data.TarifLib = "Erreur"
+		s.dataMu.Unlock()
+		return
+	}
+
+	s.dataMu.Lock()
+	s.data.CurrentTarif = now.TarifKwh
+	s.data.TarifLib = now.LibTarif
+	s.data.Stale = s.data.Stale || stale
+	s.dataMu.Unlock()
+	s.logger.Info("Tarif actuel", "tarif", now.TarifKwh, "libelle", now.LibTarif, "stale", stale)
+}
+
+// fetch utilise le cache mémoire si disponible, sinon fait une requête HTTP.
+// En cas de panne réseau, il se rabat sur la dernière entrée connue en cache
+// (mémoire ou disque), même expirée, et retourne stale=true pour le signaler
+// à l'appelant.
+func fetch[T any](s *Service, url string) (result T, stale bool, err error) {
+	s.cacheMu.RLock()
+	entry, exists := s.cache[url]
+	fresh := exists && time.Now().Before(entry.expires)
+	s.cacheMu.RUnlock()
+
+	if fresh {
+		s.logger.Info("cache_hit", "url", url)
+		s.cacheMu.Lock()
+		s.cacheHits++
+		s.cacheMu.Unlock()
+		if err := json.Unmarshal(entry.data, &result); err != nil {
+			s.logger.Error("Erreur parsing cache", "error", err)
+			var zero T
+			return zero, false, err
+		}
+		return result, false, nil
+	}
+
+	s.cacheMu.Lock()
+	s.cacheMisses++
+	s.cacheMu.Unlock()
+	s.logger.Info("cache_miss", "url", url)
+
+	body, httpErr := s.fetchHTTP(url)
+	if httpErr != nil {
+		s.cacheMu.Lock()
+		s.apiErrors++
+		s.cacheMu.Unlock()
+
+		if exists {
+			s.logger.Warn("cache_stale_served", "url", url, "error", httpErr)
+			if err := json.Unmarshal(entry.data, &result); err != nil {
+				s.logger.Error("Erreur parsing cache", "error", err)
+				var zero T
+				return zero, false, err
+			}
+			return result, true, nil
+		}
+		var zero T
+		return zero, false, httpErr
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		s.logger.Error("Erreur parsing JSON", "error", err)
+		var zero T
+		return zero, false, err
+	}
+
+	now := time.Now()
+	s.cacheMu.Lock()
+	ttl := s.config.CacheTTL
+	expires := now.Add(ttl)
+	s.cache[url] = &cacheEntry{data: body, expires: expires, fetchedAt: now}
+	s.cacheMu.Unlock()
+
+	if s.config.CacheDir != "" {
+		if err := writeThroughDiskCache(s.config.CacheDir, url, body, expires, now, s.config.MaxCacheEntries); err != nil {
+			s.logger.Warn("Erreur écriture cache disque", "url", url, "error", err)
+		}
+	}
+
+	return result, false, nil
+}
+
+// fetchHTTP effectue la requête HTTP proprement dite, sans toucher au cache.
+func (s *Service) fetchHTTP(url string) ([]byte, error) {
+	s.logger.Debug("Requête HTTP", "url", url)
+
+	s.clientMu.RLock()
+	client := s.httpClient
+	s.clientMu.RUnlock()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		s.logger.Error("Erreur HTTP", "url", url, "error", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		s.logger.Error("Erreur statut HTTP", "url", url, "status", resp.StatusCode)
+		return nil, errors.New("unexpected status code")
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		s.logger.Error("Erreur lecture réponse", "error", err)
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,71 @@
+package tempo
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// TestFetchStaleFallback vérifie que fetch se rabat sur la dernière entrée
+// connue en cache (même expirée) lorsque l'API échoue, et le signale via
+// stale=true, au lieu de remonter l'erreur à l'appelant.
+func TestFetchStaleFallback(t *testing.T) {
+	var fail atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"dateJour":"2026-07-29","codeJour":1,"periode":"normal"}`)
+	}))
+	defer ts.Close()
+
+	s := NewService(Config{APIURL: ts.URL, Timeout: time.Second, CacheTTL: time.Millisecond}, testLogger())
+	url := ts.URL + "/jourTempo/today"
+
+	first, stale, err := fetch[TempoResponse](s, url)
+	if err != nil {
+		t.Fatalf("premier fetch (API disponible): %v", err)
+	}
+	if stale {
+		t.Fatal("premier fetch: stale = true, want false (réponse fraîche de l'API)")
+	}
+
+	time.Sleep(5 * time.Millisecond) // laisse expirer l'entrée de cache mémoire
+	fail.Store(true)
+
+	second, stale, err := fetch[TempoResponse](s, url)
+	if err != nil {
+		t.Fatalf("second fetch (API en panne): %v", err)
+	}
+	if !stale {
+		t.Fatal("second fetch: stale = false, want true (repli sur le cache attendu)")
+	}
+	if second.CodeJour != first.CodeJour {
+		t.Errorf("second.CodeJour = %d, want %d (données en cache)", second.CodeJour, first.CodeJour)
+	}
+}
+
+// TestFetchHardFailureNoCache vérifie que fetch remonte l'erreur HTTP quand
+// aucune entrée de cache n'existe pour se rabattre.
+func TestFetchHardFailureNoCache(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	s := NewService(Config{APIURL: ts.URL, Timeout: time.Second, CacheTTL: time.Minute}, testLogger())
+
+	if _, _, err := fetch[TempoResponse](s, ts.URL+"/jourTempo/today"); err == nil {
+		t.Fatal("fetch sans cache ni API disponible: err = nil, want une erreur")
+	}
+}